@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/deepfence/ThreatMapper/deepfence_server/model"
+	"github.com/deepfence/ThreatMapper/deepfence_server/pkg/registry/gitlab"
+	"github.com/deepfence/golang_deepfence_sdk/utils/directory"
+	httpext "github.com/go-playground/pkg/v5/net/http"
+)
+
+// GitlabGroupDiscoveryRequest describes a parent GitLab group to walk for
+// onboarding, reusing the group's own RegistryGitlab credentials.
+type GitlabGroupDiscoveryRequest struct {
+	Registry gitlab.RegistryGitlab `json:"registry" validate:"required"`
+	GroupID  string                `json:"group_id" validate:"required"`
+	PathGlob string                `json:"path_glob"`
+	// RegistryID identifies the already-persisted RegistryGitlab these
+	// credentials belong to. Required by OnboardGitlabGroup (it scopes the
+	// persisted group so two registries can't collide on the same
+	// group_id); unused by the dry-run.
+	RegistryID int64 `json:"registry_id"`
+}
+
+// DiscoverGitlabGroupProjects serves the dry-run endpoint: it returns the
+// projects that would be onboarded as registries for the given group,
+// without writing anything.
+func (h *Handler) DiscoverGitlabGroupProjects(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var req GitlabGroupDiscoveryRequest
+	err := httpext.DecodeJSON(r, httpext.NoQueryParams, MaxPostRequestSize, &req)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	err = h.Validator.Struct(req)
+	if err != nil {
+		respondError(&ValidatorError{err}, w)
+		return
+	}
+	discovered, err := req.Registry.DiscoverProjects(r.Context(), req.GroupID, req.PathGlob)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	httpext.JSON(w, http.StatusOK, discovered)
+}
+
+// OnboardedRegistry pairs a materialized child RegistryGitlab with the ID
+// of the persisted row backing it, so a caller can tell a freshly-created
+// entry apart from one that already existed from a prior discovery run.
+type OnboardedRegistry struct {
+	ID       int64                 `json:"id"`
+	Registry gitlab.RegistryGitlab `json:"registry"`
+}
+
+// OnboardGitlabGroup runs discovery for the given group, persists the parent
+// group so a periodic job can refresh it later, and upserts one child
+// registry row per discovered project, keyed on (group, GitLab project ID)
+// so re-running discovery updates existing entries instead of duplicating
+// them and losing their scan state.
+func (h *Handler) OnboardGitlabGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := directory.WithGlobalContext(r.Context())
+	pgClient, err := directory.PostgresClient(ctx)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	defer r.Body.Close()
+	var req GitlabGroupDiscoveryRequest
+	err = httpext.DecodeJSON(r, httpext.NoQueryParams, MaxPostRequestSize, &req)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	err = h.Validator.Struct(req)
+	if err != nil {
+		respondError(&ValidatorError{err}, w)
+		return
+	}
+	if req.RegistryID == 0 {
+		respondError(&ValidatorError{
+			errors.New("Key: 'GitlabGroupDiscoveryRequest.RegistryID' Error:required")}, w)
+		return
+	}
+
+	discovered, err := req.Registry.DiscoverProjects(ctx, req.GroupID, req.PathGlob)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+
+	group, err := model.AddRegistryGitlabGroup(ctx, pgClient, model.RegistryGitlabGroup{
+		RegistryID: req.RegistryID,
+		GroupID:    req.GroupID,
+		PathGlob:   req.PathGlob,
+	})
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+
+	onboarded := make([]OnboardedRegistry, 0, len(discovered))
+	for _, project := range discovered {
+		id, err := model.UpsertDiscoveredRegistry(ctx, pgClient, group.ID, project)
+		if err != nil {
+			respondError(err, w)
+			return
+		}
+		onboarded = append(onboarded, OnboardedRegistry{
+			ID:       id,
+			Registry: req.Registry.ToRegistry(project),
+		})
+	}
+
+	httpext.JSON(w, http.StatusOK, struct {
+		Group     model.RegistryGitlabGroup `json:"group"`
+		Onboarded []OnboardedRegistry       `json:"onboarded"`
+	}{Group: group, Onboarded: onboarded})
+}
@@ -1,16 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"net/http"
-	"net/url"
 	"strconv"
 
 	"github.com/deepfence/ThreatMapper/deepfence_server/model"
 	"github.com/deepfence/golang_deepfence_sdk/utils/directory"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
 	httpext "github.com/go-playground/pkg/v5/net/http"
 )
 
@@ -64,25 +64,21 @@ func (h *Handler) UpdateGlobalSettings(w http.ResponseWriter, r *http.Request) {
 			errors.New("Key: 'SettingUpdateRequest.ID' Error:invalid")}, w)
 		return
 	}
-	var value interface{}
-	switch currentSettings.Key {
-	case model.ConsoleURLSettingKey:
-		consoleUrl := fmt.Sprintf("%s", req.Value)
-		var parsedUrl *url.URL
-		if parsedUrl, err = url.ParseRequestURI(consoleUrl); err != nil {
-			respondError(&ValidatorError{
-				errors.New("Key: 'SettingUpdateRequest.Value' Error:must be url")}, w)
-			return
-		}
-		value = parsedUrl.Scheme + "://" + parsedUrl.Host
-	case model.InactiveNodesDeleteScanResultsKey:
-		val, ok := req.Value.(float64)
-		if !ok {
-			respondError(&ValidatorError{
-				errors.New("Key: 'SettingUpdateRequest.Value' Error:must be integer")}, w)
-			return
-		}
-		value = int(math.Round(val))
+	spec, err := model.GetSettingSpec(currentSettings.Key)
+	if err != nil {
+		respondError(&ValidatorError{err}, w)
+		return
+	}
+	rawValue, err := json.Marshal(req.Value)
+	if err != nil {
+		respondError(&BadDecoding{err}, w)
+		return
+	}
+	value, err := spec.Validate(rawValue)
+	if err != nil {
+		respondError(&ValidatorError{
+			fmt.Errorf("Key: 'SettingUpdateRequest.Value' Error:%w", err)}, w)
+		return
 	}
 	setting := model.Setting{
 		ID:  req.ID,
@@ -94,10 +90,91 @@ func (h *Handler) UpdateGlobalSettings(w http.ResponseWriter, r *http.Request) {
 		},
 		IsVisibleOnUi: currentSettings.IsVisibleOnUi,
 	}
-	err = setting.Update(ctx, pgClient)
+	actor, err := actorID(r)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	err = model.UpdateSettingWithAudit(ctx, pgClient, spec, setting, model.SettingsAudit{
+		UserID:    actor,
+		Key:       req.Key,
+		OldValue:  currentSettings.Value.Value,
+		NewValue:  value,
+		SourceIP:  r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+	})
 	if err != nil {
 		respondError(err, w)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// GetSettingsAuditLog serves GET /settings/audit, returning the audit trail
+// of global settings changes, optionally filtered by key/actor and paginated
+// with offset/limit query params.
+func (h *Handler) GetSettingsAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := directory.WithGlobalContext(r.Context())
+	pgClient, err := directory.PostgresClient(ctx)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	query := r.URL.Query()
+	filter := model.SettingsAuditFilter{
+		Key: query.Get("key"),
+	}
+	if actor := query.Get("actor"); actor != "" {
+		filter.UserID, err = strconv.ParseInt(actor, 10, 64)
+		if err != nil {
+			respondError(&BadDecoding{err}, w)
+			return
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		parsed, err := strconv.ParseInt(offset, 10, 32)
+		if err != nil {
+			respondError(&BadDecoding{err}, w)
+			return
+		}
+		filter.Offset = int32(parsed)
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			respondError(&BadDecoding{err}, w)
+			return
+		}
+		filter.Limit = int32(parsed)
+	}
+	audit, err := model.GetSettingsAudit(ctx, pgClient, filter)
+	if err != nil {
+		respondError(err, w)
+		return
+	}
+	httpext.JSON(w, http.StatusOK, audit)
+}
+
+// actorID returns the authenticated user ID for r, read from the "sub"
+// claim the auth middleware verifies and stashes on the request context for
+// every protected route -- JWT's standard subject claim (RFC 7519), which
+// jwtauth round-trips as a string rather than a number. Settings endpoints
+// sit behind that middleware, so a missing or malformed claim here means
+// something upstream is broken; it must be surfaced as an error rather than
+// silently attributed to actor 0, which would make the audit log lie about
+// who made a change.
+func actorID(r *http.Request) (int64, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return 0, fmt.Errorf("reading auth claims: %w", err)
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok || sub == "" {
+		return 0, errors.New(`auth claims missing "sub"`)
+	}
+	id, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing sub claim as user id: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/deepfence/ThreatMapper/deepfence_server/pkg/registry/gitlab"
+	postgresqlDb "github.com/deepfence/ThreatMapper/deepfence_utils/postgresqlDb"
+)
+
+// RegistryGitlabGroup is a persisted parent GitLab group/subgroup that
+// registries were discovered from. Keeping it as a first-class object
+// records which credentials and path_glob a given onboarding run used, so
+// a later manual re-onboard of the same group upserts against it instead
+// of starting over.
+type RegistryGitlabGroup struct {
+	ID              int64      `json:"id"`
+	RegistryID      int64      `json:"registry_id"`
+	GroupID         string     `json:"group_id"`
+	PathGlob        string     `json:"path_glob"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastRefreshedAt *time.Time `json:"last_refreshed_at"`
+}
+
+// AddRegistryGitlabGroup upserts group, keyed on (registry, group_id), so
+// re-onboarding the same group updates the existing row instead of
+// duplicating it.
+func AddRegistryGitlabGroup(ctx context.Context, pgClient *postgresqlDb.Queries, group RegistryGitlabGroup) (RegistryGitlabGroup, error) {
+	row, err := pgClient.UpsertRegistryGitlabGroup(ctx, postgresqlDb.UpsertRegistryGitlabGroupParams{
+		RegistryID: group.RegistryID,
+		GroupID:    group.GroupID,
+		PathGlob:   group.PathGlob,
+	})
+	if err != nil {
+		return RegistryGitlabGroup{}, err
+	}
+	return registryGitlabGroupFromRow(row), nil
+}
+
+func registryGitlabGroupFromRow(row postgresqlDb.RegistryGitlabGroup) RegistryGitlabGroup {
+	group := RegistryGitlabGroup{
+		ID:         row.ID,
+		RegistryID: row.RegistryID,
+		GroupID:    row.GroupID,
+		PathGlob:   row.PathGlob,
+		CreatedAt:  row.CreatedAt,
+	}
+	if row.LastRefreshedAt.Valid {
+		group.LastRefreshedAt = &row.LastRefreshedAt.Time
+	}
+	return group
+}
+
+// UpsertDiscoveredRegistry persists (or refreshes) the child registry
+// materialized from a project discovered under groupRefID, keyed on
+// (group, project ID) so re-running discovery upserts the existing row
+// instead of creating a duplicate and resetting whatever scan state
+// referenced it. True de-duplication on repository digest can't happen at
+// onboarding time: a digest belongs to an individual image found while
+// scanning the registry, not to the project itself, so the project's
+// GitLab ID is the most stable identity available this early.
+func UpsertDiscoveredRegistry(ctx context.Context, pgClient *postgresqlDb.Queries, groupRefID int64, project gitlab.DiscoveredProject) (int64, error) {
+	row, err := pgClient.UpsertRegistryGitlabChild(ctx, postgresqlDb.UpsertRegistryGitlabChildParams{
+		GroupRefID:        groupRefID,
+		ProjectID:         project.ID,
+		PathWithNamespace: project.PathWithNamespace,
+		RegistryUrl:       project.ContainerRegistryImagePrefix,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return row.ID, nil
+}
@@ -0,0 +1,300 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	postgresqlDb "github.com/deepfence/ThreatMapper/deepfence_utils/postgresqlDb"
+)
+
+const (
+	ConsoleURLSettingKey              = "console_url"
+	InactiveNodesDeleteScanResultsKey = "inactive_nodes_delete_scan_results"
+	ScanConcurrencyKey                = "scan_concurrency"
+	DefaultScanScheduleKey            = "default_scan_schedule"
+	PasswordPolicyKey                 = "password_policy"
+	TelemetryEnabledKey               = "telemetry_enabled"
+	SessionTimeoutKey                 = "session_timeout"
+)
+
+type SettingValue struct {
+	Label       string      `json:"label"`
+	Value       interface{} `json:"value"`
+	Description string      `json:"description"`
+}
+
+type Setting struct {
+	ID            int64         `json:"id"`
+	Key           string        `json:"key"`
+	Value         *SettingValue `json:"value"`
+	IsVisibleOnUi bool          `json:"is_visible_on_ui"`
+}
+
+type SettingUpdateRequest struct {
+	ID    int64       `json:"id"`
+	Key   string      `json:"key" validate:"required"`
+	Value interface{} `json:"value" validate:"required"`
+}
+
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireNumber    bool `json:"require_number"`
+	RequireSymbol    bool `json:"require_symbol"`
+}
+
+// SettingSpec describes everything UpdateGlobalSettings/GetVisibleSettings
+// need to know about one global setting, so that adding a setting is a
+// matter of registering a spec rather than editing a switch statement.
+type SettingSpec struct {
+	// Default documents the value this setting is seeded with (see
+	// migrations/0003_seed_new_global_settings.up.sql); every key in
+	// settingSpecs must have a matching seeded row, since UpdateGlobalSettings
+	// and GetVisibleSettings only operate on keys that already have one.
+	Default interface{}
+	// IsSecret redacts Value in GetVisibleSettings responses.
+	IsSecret bool
+	// IsVisibleOnUi controls whether GetVisibleSettings returns this key
+	// at all.
+	IsVisibleOnUi bool
+	// Validate decodes and validates a raw incoming value, returning the
+	// normalized value to persist.
+	Validate func(raw json.RawMessage) (interface{}, error)
+}
+
+// settingSpecs is the registry of known global settings. Adding a new
+// setting is a matter of adding an entry here plus its seeded row (see
+// SettingSpec.Default); UpdateGlobalSettings and GetVisibleSettings need no
+// changes.
+var settingSpecs = map[string]SettingSpec{
+	ConsoleURLSettingKey: {
+		Default:       "",
+		IsVisibleOnUi: true,
+		Validate:      validateURLSetting,
+	},
+	InactiveNodesDeleteScanResultsKey: {
+		Default:       90,
+		IsVisibleOnUi: true,
+		Validate:      validateIntSetting(1, 365),
+	},
+	ScanConcurrencyKey: {
+		Default:       5,
+		IsVisibleOnUi: true,
+		Validate:      validateIntSetting(1, 100),
+	},
+	DefaultScanScheduleKey: {
+		Default:       "0 0 * * *",
+		IsVisibleOnUi: true,
+		Validate:      validateCronSetting,
+	},
+	PasswordPolicyKey: {
+		Default:       PasswordPolicy{MinLength: 8},
+		IsVisibleOnUi: true,
+		Validate:      validatePasswordPolicySetting,
+	},
+	TelemetryEnabledKey: {
+		Default:       true,
+		IsVisibleOnUi: true,
+		Validate:      validateBoolSetting,
+	},
+	SessionTimeoutKey: {
+		Default:       24 * time.Hour,
+		IsSecret:      false,
+		IsVisibleOnUi: true,
+		Validate:      validateDurationSetting,
+	},
+}
+
+// GetSettingSpec looks up the spec registered for key, so handlers can
+// validate/redact without knowing about individual settings.
+func GetSettingSpec(key string) (SettingSpec, error) {
+	spec, ok := settingSpecs[key]
+	if !ok {
+		return SettingSpec{}, fmt.Errorf("no setting spec registered for key %q", key)
+	}
+	return spec, nil
+}
+
+func validateURLSetting(raw json.RawMessage) (interface{}, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.New("must be a url")
+	}
+	parsedURL, err := url.ParseRequestURI(value)
+	if err != nil {
+		return nil, errors.New("must be a url")
+	}
+	return parsedURL.Scheme + "://" + parsedURL.Host, nil
+}
+
+func validateIntSetting(min, max int) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var value float64
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		rounded := int(math.Round(value))
+		if rounded < min || rounded > max {
+			return nil, fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return rounded, nil
+	}
+}
+
+func validateBoolSetting(raw json.RawMessage) (interface{}, error) {
+	var value bool
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.New("must be a boolean")
+	}
+	return value, nil
+}
+
+func validateDurationSetting(raw json.RawMessage) (interface{}, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.New("must be a duration string")
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, errors.New("must be a valid duration, e.g. \"24h\"")
+	}
+	return duration, nil
+}
+
+// cronFieldRanges gives the inclusive min/max for each of a 5-field cron
+// expression's positions, in order: minute, hour, day-of-month, month,
+// day-of-week.
+var cronFieldRanges = [5][2]int{
+	{0, 59},
+	{0, 23},
+	{1, 31},
+	{1, 12},
+	{0, 6},
+}
+
+func validateCronSetting(raw json.RawMessage) (interface{}, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.New("must be a 5-field cron expression")
+	}
+	fields := strings.Fields(value)
+	if len(fields) != 5 {
+		return nil, errors.New("must be a 5-field cron expression")
+	}
+	for i, field := range fields {
+		if err := validateCronField(field, cronFieldRanges[i][0], cronFieldRanges[i][1]); err != nil {
+			return nil, fmt.Errorf("field %d (%q): %w", i+1, field, err)
+		}
+	}
+	return value, nil
+}
+
+// validateCronField checks one comma-separated cron field against the
+// standard grammar: "*", a single value, a "-" range, either optionally
+// followed by a "/" step, with every value required to fall within
+// [min, max].
+func validateCronField(field string, min, max int) error {
+	for _, term := range strings.Split(field, ",") {
+		base, step, hasStep := strings.Cut(term, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return fmt.Errorf("invalid step %q", step)
+			}
+		}
+		if base == "*" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(base, "-")
+		loVal, err := strconv.Atoi(lo)
+		if err != nil || loVal < min || loVal > max {
+			return fmt.Errorf("value %q out of range %d-%d", lo, min, max)
+		}
+		if isRange {
+			hiVal, err := strconv.Atoi(hi)
+			if err != nil || hiVal < min || hiVal > max || hiVal < loVal {
+				return fmt.Errorf("value %q out of range %d-%d", hi, min, max)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePasswordPolicySetting(raw json.RawMessage) (interface{}, error) {
+	var value PasswordPolicy
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, errors.New("must be a password policy object")
+	}
+	if value.MinLength < 8 {
+		return nil, errors.New("min_length must be at least 8")
+	}
+	return value, nil
+}
+
+// GetVisibleSettings returns every registered setting that is visible on
+// the UI, with secret values redacted.
+func GetVisibleSettings(ctx context.Context, pgClient *postgresqlDb.Queries) ([]Setting, error) {
+	rows, err := pgClient.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings := make([]Setting, 0, len(rows))
+	for _, row := range rows {
+		spec, ok := settingSpecs[row.Key]
+		if !ok || !spec.IsVisibleOnUi {
+			continue
+		}
+		setting, err := settingFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		if spec.IsSecret {
+			setting.Value.Value = nil
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// GetSettingByKey fetches the current row for key, for use before an
+// update so the handler can check the request's ID against it.
+func GetSettingByKey(ctx context.Context, pgClient *postgresqlDb.Queries, key string) (Setting, error) {
+	row, err := pgClient.GetSettingByKey(ctx, key)
+	if err != nil {
+		return Setting{}, err
+	}
+	return settingFromRow(row)
+}
+
+func settingFromRow(row postgresqlDb.Setting) (Setting, error) {
+	var value SettingValue
+	if err := json.Unmarshal(row.Value, &value); err != nil {
+		return Setting{}, err
+	}
+	return Setting{
+		ID:            row.ID,
+		Key:           row.Key,
+		Value:         &value,
+		IsVisibleOnUi: row.IsVisibleOnUi,
+	}, nil
+}
+
+// Update persists s, overwriting the row with a matching ID.
+func (s *Setting) Update(ctx context.Context, pgClient *postgresqlDb.Queries) error {
+	value, err := json.Marshal(s.Value)
+	if err != nil {
+		return err
+	}
+	return pgClient.UpdateSetting(ctx, postgresqlDb.UpdateSettingParams{
+		ID:            s.ID,
+		Key:           s.Key,
+		Value:         value,
+		IsVisibleOnUi: s.IsVisibleOnUi,
+	})
+}
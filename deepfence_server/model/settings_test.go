@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestValidateURLSetting(t *testing.T) {
+	got, err := validateURLSetting(json.RawMessage(`"https://example.com/some/path"`))
+	if err != nil {
+		t.Fatalf("validateURLSetting() error = %v", err)
+	}
+	if got != "https://example.com" {
+		t.Errorf("validateURLSetting() = %q, want scheme+host only", got)
+	}
+
+	if _, err := validateURLSetting(json.RawMessage(`"not a url"`)); err == nil {
+		t.Error("expected an error for a malformed url, got nil")
+	}
+	if _, err := validateURLSetting(json.RawMessage(`123`)); err == nil {
+		t.Error("expected an error for a non-string value, got nil")
+	}
+}
+
+func TestValidateIntSetting(t *testing.T) {
+	validate := validateIntSetting(1, 100)
+
+	got, err := validate(json.RawMessage(`42.4`))
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	if got != 42 {
+		t.Errorf("validate() = %v, want 42 (rounded)", got)
+	}
+
+	if _, err := validate(json.RawMessage(`0`)); err == nil {
+		t.Error("expected an error for a value below the minimum, got nil")
+	}
+	if _, err := validate(json.RawMessage(`101`)); err == nil {
+		t.Error("expected an error for a value above the maximum, got nil")
+	}
+	if _, err := validate(json.RawMessage(`"nope"`)); err == nil {
+		t.Error("expected an error for a non-numeric value, got nil")
+	}
+}
+
+func TestValidateBoolSetting(t *testing.T) {
+	got, err := validateBoolSetting(json.RawMessage(`true`))
+	if err != nil {
+		t.Fatalf("validateBoolSetting() error = %v", err)
+	}
+	if got != true {
+		t.Errorf("validateBoolSetting() = %v, want true", got)
+	}
+
+	if _, err := validateBoolSetting(json.RawMessage(`"true"`)); err == nil {
+		t.Error("expected an error for a non-boolean value, got nil")
+	}
+}
+
+func TestValidateDurationSetting(t *testing.T) {
+	got, err := validateDurationSetting(json.RawMessage(`"24h"`))
+	if err != nil {
+		t.Fatalf("validateDurationSetting() error = %v", err)
+	}
+	if got != 24*time.Hour {
+		t.Errorf("validateDurationSetting() = %v, want 24h", got)
+	}
+
+	if _, err := validateDurationSetting(json.RawMessage(`"not-a-duration"`)); err == nil {
+		t.Error("expected an error for an invalid duration string, got nil")
+	}
+}
+
+func TestValidateCronSetting(t *testing.T) {
+	valid := []string{
+		"0 0 * * *",
+		"*/15 * * * *",
+		"0,30 8-17 1,15 * 1-5",
+	}
+	for _, expr := range valid {
+		if _, err := validateCronSetting(json.RawMessage(`"` + expr + `"`)); err != nil {
+			t.Errorf("validateCronSetting(%q) error = %v, want nil", expr, err)
+		}
+	}
+
+	if _, err := validateCronSetting(json.RawMessage(`"0 0 * *"`)); err == nil {
+		t.Error("expected an error for a cron expression with fewer than 5 fields, got nil")
+	}
+	if _, err := validateCronSetting(json.RawMessage(`"foo bar baz qux quux"`)); err == nil {
+		t.Error("expected an error for non-cron garbage with 5 fields, got nil")
+	}
+	if _, err := validateCronSetting(json.RawMessage(`"60 0 * * *"`)); err == nil {
+		t.Error("expected an error for a minute field out of range, got nil")
+	}
+	if _, err := validateCronSetting(json.RawMessage(`"0 0 32 * *"`)); err == nil {
+		t.Error("expected an error for a day-of-month field out of range, got nil")
+	}
+	if _, err := validateCronSetting(json.RawMessage(`"0 0 * 13 *"`)); err == nil {
+		t.Error("expected an error for a month field out of range, got nil")
+	}
+}
+
+func TestValidatePasswordPolicySetting(t *testing.T) {
+	got, err := validatePasswordPolicySetting(json.RawMessage(`{"min_length":12,"require_uppercase":true}`))
+	if err != nil {
+		t.Fatalf("validatePasswordPolicySetting() error = %v", err)
+	}
+	policy, ok := got.(PasswordPolicy)
+	if !ok {
+		t.Fatalf("validatePasswordPolicySetting() returned %T, want PasswordPolicy", got)
+	}
+	if policy.MinLength != 12 || !policy.RequireUppercase {
+		t.Errorf("validatePasswordPolicySetting() = %+v, want MinLength=12 RequireUppercase=true", policy)
+	}
+
+	if _, err := validatePasswordPolicySetting(json.RawMessage(`{"min_length":4}`)); err == nil {
+		t.Error("expected an error when min_length is below 8, got nil")
+	}
+}
@@ -0,0 +1,96 @@
+package gitlab
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+const testPEMCert = `-----BEGIN CERTIFICATE-----
+MIIBOjCB4KADAgECAgEBMAoGCCqGSM49BAMCMBIxEDAOBgNVBAoTB0FjbWUgQ28w
+HhcNMjQwMTAxMDAwMDAwWhcNMzQwMTAxMDAwMDAwWjASMRAwDgYDVQQKEwdBY21l
+IENvMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEFzujnRiBrg+kJlVdEI+xIA6V
+uIjVnO7QAbUnW5Eq/JTW2LIAxNsv6Gpg98dPkhpP07/ZVboHPIWNld7CPxyVT6Mn
+MCUwDgYDVR0PAQH/BAQDAgeAMBMGA1UdJQQMMAoGCCsGAQUFBwMBMAoGCCqGSM49
+BAMCA0kAMEYCIQDZhlt2QD7THJ8BlmF1yAVDT4f2NpDicHXqcKw67nFGmwIhAPo2
+Htjlbp1qMXJEIRPiq+x/SQgR4Ob0+uwIB+b3QnFr
+-----END CERTIFICATE-----`
+
+func TestDecodeCABundleAcceptsValidPEM(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(testPEMCert))
+	pool, err := decodeCABundle(encoded)
+	if err != nil {
+		t.Fatalf("decodeCABundle() error = %v", err)
+	}
+	if pool == nil {
+		t.Fatal("decodeCABundle() returned a nil pool")
+	}
+}
+
+func TestDecodeCABundleRejectsInvalidBase64(t *testing.T) {
+	if _, err := decodeCABundle("not-base64!!"); err == nil {
+		t.Error("expected an error for invalid base64, got nil")
+	}
+}
+
+func TestDecodeCABundleRejectsNonPEM(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("this is not a certificate"))
+	if _, err := decodeCABundle(encoded); err == nil {
+		t.Error("expected an error for a non-PEM bundle, got nil")
+	}
+}
+
+func validRegistry() *RegistryGitlab {
+	return &RegistryGitlab{
+		Name:         "my-registry",
+		RegistryType: "gitlab",
+		NonSecret: NonSecret{
+			GitlabServerURL:   "https://gitlab.example.com",
+			GitlabRegistryURL: "https://registry.example.com",
+		},
+		Secret: Secret{GitlabToken: "token"},
+	}
+}
+
+func TestValidateRejectsUnknownScheme(t *testing.T) {
+	r := validRegistry()
+	r.NonSecret.GitlabServerURL = "ftp://gitlab.example.com"
+	if err := r.Validate(); err == nil {
+		t.Error("expected an error for a non-http(s) scheme, got nil")
+	}
+}
+
+func TestValidateRequiresTokenInTokenMode(t *testing.T) {
+	r := validRegistry()
+	r.Secret.GitlabToken = ""
+	if err := r.Validate(); err == nil {
+		t.Error("expected an error when auth_mode is token and gitlab_access_token is empty, got nil")
+	}
+}
+
+func TestValidateRequiresJWTSecretInJWTMode(t *testing.T) {
+	r := validRegistry()
+	r.NonSecret.AuthMode = AuthModeJWT
+	r.Secret.JWTSecret = ""
+	if err := r.Validate(); err == nil {
+		t.Error("expected an error when auth_mode is jwt and jwt_secret is empty, got nil")
+	}
+
+	r.Secret.JWTSecret = "a-secret"
+	if err := r.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil once jwt_secret is set", err)
+	}
+}
+
+func TestValidateRejectsUnparsableCABundle(t *testing.T) {
+	r := validRegistry()
+	r.NonSecret.CAFile = base64.StdEncoding.EncodeToString([]byte("garbage"))
+	if err := r.Validate(); err == nil {
+		t.Error("expected an error for a CA file that isn't valid PEM, got nil")
+	}
+}
+
+func TestValidateAcceptsWellFormedRegistry(t *testing.T) {
+	if err := validRegistry().Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,155 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DiscoveredProject is one GitLab project surfaced by DiscoverProjects that
+// is eligible to be onboarded as its own registry entry.
+type DiscoveredProject struct {
+	ID                           int64  `json:"id"`
+	PathWithNamespace            string `json:"path_with_namespace"`
+	ContainerRegistryImagePrefix string `json:"container_registry_image_prefix"`
+}
+
+// gitlabProject mirrors the subset of GitLab's project API response this
+// package cares about.
+type gitlabProject struct {
+	ID                           int64  `json:"id"`
+	PathWithNamespace            string `json:"path_with_namespace"`
+	ContainerRegistryEnabled     bool   `json:"container_registry_enabled"`
+	ContainerRegistryImagePrefix string `json:"container_registry_image_prefix"`
+}
+
+// DiscoverProjects walks groupID's projects, including subgroups, using
+// GitLab's keyset pagination, and returns one entry per project that has the
+// container registry enabled and whose path_with_namespace matches pathGlob
+// (empty matches everything). Because discovery recurses into subgroups,
+// "*" in pathGlob matches across "/" as well, so e.g. "myorg/*" covers
+// myorg/team-a/service. It performs no writes, so it doubles as the
+// implementation of the discovery dry-run.
+func (r *RegistryGitlab) DiscoverProjects(ctx context.Context, groupID, pathGlob string) ([]DiscoveredProject, error) {
+	client, err := r.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	pathMatcher, err := compilePathGlob(pathGlob)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"include_subgroups": {"true"},
+		"per_page":          {"100"},
+		"pagination":        {"keyset"},
+		"order_by":          {"id"},
+		"sort":              {"asc"},
+	}
+	next := fmt.Sprintf("%s/api/v4/groups/%s/projects?%s",
+		strings.TrimRight(r.NonSecret.GitlabServerURL, "/"), url.PathEscape(groupID), query.Encode())
+
+	var discovered []DiscoveredProject
+	for next != "" {
+		projects, nextPage, err := r.fetchProjectsPage(ctx, client, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			if !p.ContainerRegistryEnabled {
+				continue
+			}
+			if pathMatcher != nil && !pathMatcher.MatchString(p.PathWithNamespace) {
+				continue
+			}
+			discovered = append(discovered, DiscoveredProject{
+				ID:                           p.ID,
+				PathWithNamespace:            p.PathWithNamespace,
+				ContainerRegistryImagePrefix: p.ContainerRegistryImagePrefix,
+			})
+		}
+		next = nextPage
+	}
+	return discovered, nil
+}
+
+func (r *RegistryGitlab) fetchProjectsPage(ctx context.Context, client *http.Client, pageURL string) ([]gitlabProject, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := r.authenticate(req); err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("gitlab returned %s fetching %s", resp.Status, pageURL)
+	}
+	var projects []gitlabProject
+	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
+		return nil, "", err
+	}
+	return projects, nextPageURL(resp.Header.Get("Link")), nil
+}
+
+// compilePathGlob turns a shell-style glob ("*" any run of characters, "?"
+// any single character) into a regexp anchored to the full string, or
+// returns nil when pathGlob is empty. Unlike path.Match, "*" is allowed to
+// match "/" here, since discovery walks subgroups and a glob like
+// "myorg/*" is expected to reach nested projects such as
+// "myorg/team-a/service".
+func compilePathGlob(pathGlob string) (*regexp.Regexp, error) {
+	if pathGlob == "" {
+		return nil, nil
+	}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for _, r := range pathGlob {
+		switch r {
+		case '*':
+			pattern.WriteString(".*")
+		case '?':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid path glob %q: %w", pathGlob, err)
+	}
+	return re, nil
+}
+
+// nextPageURL extracts the rel="next" target from a GitLab Link header,
+// returning "" once there is no further page.
+func nextPageURL(linkHeader string) string {
+	for _, part := range strings.Split(linkHeader, ",") {
+		sections := strings.Split(strings.TrimSpace(part), ";")
+		if len(sections) != 2 || strings.TrimSpace(sections[1]) != `rel="next"` {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(sections[0]), "<>")
+	}
+	return ""
+}
+
+// ToRegistry materializes a child RegistryGitlab entry for a discovered
+// project, inheriting this group's credentials and TLS settings and naming
+// the entry after the project's path.
+func (r *RegistryGitlab) ToRegistry(project DiscoveredProject) RegistryGitlab {
+	child := *r
+	child.Name = project.PathWithNamespace
+	child.NonSecret.GitlabRegistryURL = project.ContainerRegistryImagePrefix
+	return child
+}
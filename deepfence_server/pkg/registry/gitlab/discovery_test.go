@@ -0,0 +1,75 @@
+package gitlab
+
+import "testing"
+
+func TestCompilePathGlobMatchesAcrossSlash(t *testing.T) {
+	re, err := compilePathGlob("myorg/*")
+	if err != nil {
+		t.Fatalf("compilePathGlob() error = %v", err)
+	}
+	if !re.MatchString("myorg/team-a/service") {
+		t.Error("expected \"myorg/*\" to match nested subgroup paths")
+	}
+	if !re.MatchString("myorg/service") {
+		t.Error("expected \"myorg/*\" to match a direct child")
+	}
+	if re.MatchString("otherorg/service") {
+		t.Error("expected \"myorg/*\" not to match a different org")
+	}
+}
+
+func TestCompilePathGlobEscapesLiteralCharacters(t *testing.T) {
+	re, err := compilePathGlob("myorg/a.b+c")
+	if err != nil {
+		t.Fatalf("compilePathGlob() error = %v", err)
+	}
+	if !re.MatchString("myorg/a.b+c") {
+		t.Error("expected literal characters to match themselves")
+	}
+	if re.MatchString("myorg/aXb+c") {
+		t.Error("expected '.' to be escaped rather than matching any character")
+	}
+}
+
+func TestCompilePathGlobQuestionMarkMatchesSingleChar(t *testing.T) {
+	re, err := compilePathGlob("myorg/service-?")
+	if err != nil {
+		t.Fatalf("compilePathGlob() error = %v", err)
+	}
+	if !re.MatchString("myorg/service-1") {
+		t.Error("expected '?' to match a single character")
+	}
+	if re.MatchString("myorg/service-12") {
+		t.Error("expected '?' not to match more than one character")
+	}
+}
+
+func TestCompilePathGlobEmptyReturnsNilMatcher(t *testing.T) {
+	re, err := compilePathGlob("")
+	if err != nil {
+		t.Fatalf("compilePathGlob() error = %v", err)
+	}
+	if re != nil {
+		t.Errorf("expected a nil matcher for an empty glob, got %v", re)
+	}
+}
+
+func TestNextPageURLReturnsNextTarget(t *testing.T) {
+	header := `<https://gitlab.example.com/api/v4/groups/1/projects?page=1>; rel="prev", <https://gitlab.example.com/api/v4/groups/1/projects?page=3>; rel="next"`
+	if got, want := nextPageURL(header), "https://gitlab.example.com/api/v4/groups/1/projects?page=3"; got != want {
+		t.Errorf("nextPageURL() = %q, want %q", got, want)
+	}
+}
+
+func TestNextPageURLReturnsEmptyWithoutNextRel(t *testing.T) {
+	header := `<https://gitlab.example.com/api/v4/groups/1/projects?page=1>; rel="prev"`
+	if got := nextPageURL(header); got != "" {
+		t.Errorf("nextPageURL() = %q, want empty string", got)
+	}
+}
+
+func TestNextPageURLHandlesEmptyHeader(t *testing.T) {
+	if got := nextPageURL(""); got != "" {
+		t.Errorf("nextPageURL() = %q, want empty string", got)
+	}
+}
@@ -0,0 +1,97 @@
+package gitlab
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwtLifetime is how long a minted JWT stays valid for. Kept short so a
+// leaked header value is only ever useful for a brief window.
+const jwtLifetime = 30 * time.Second
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// signingKeys caches the trimmed secret bytes per registry name, rather
+// than per raw secret value, so the cache holds at most one entry for each
+// live registry instead of growing forever as a "rotating shared secret"
+// is, well, rotated over the life of the process.
+var signingKeys sync.Map // map[string]*cachedSigningKey
+
+type cachedSigningKey struct {
+	secret string
+	key    []byte
+}
+
+type jwtClaims struct {
+	Iss string `json:"iss"`
+	Iat int64  `json:"iat"`
+	Exp int64  `json:"exp"`
+	Jti string `json:"jti"`
+}
+
+// signingKey returns the trimmed key bytes for secret, keyed by
+// registryName. A cached entry is reused only while its secret still
+// matches the current one; a rotated secret replaces it in place rather
+// than accumulating alongside it.
+func signingKey(registryName, secret string) []byte {
+	trimmed := strings.TrimSpace(secret)
+	if cached, ok := signingKeys.Load(registryName); ok {
+		entry := cached.(*cachedSigningKey)
+		if entry.secret == trimmed {
+			return entry.key
+		}
+	}
+	entry := &cachedSigningKey{secret: trimmed, key: []byte(trimmed)}
+	signingKeys.Store(registryName, entry)
+	return entry.key
+}
+
+// jwtToken mints a short-lived HS256 JWT authenticating this registry, for
+// use with AuthModeJWT, and returns it base64-encoded for the configured
+// header.
+func (r *RegistryGitlab) jwtToken(now time.Time) (string, error) {
+	jti, err := randomJTI()
+	if err != nil {
+		return "", fmt.Errorf("generating jti: %w", err)
+	}
+	claims, err := json.Marshal(jwtClaims{
+		Iss: "deepfence",
+		Iat: now.Unix(),
+		Exp: now.Add(jwtLifetime).Unix(),
+		Jti: jti,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	mac := hmac.New(sha256.New, signingKey(r.Name, r.Secret.JWTSecret))
+	mac.Write([]byte(payload))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return base64.StdEncoding.EncodeToString([]byte(payload + "." + signature)), nil
+}
+
+func randomJTI() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// jwtHeaderName returns the header a minted JWT should be sent in.
+func (r *RegistryGitlab) jwtHeaderName() string {
+	if r.NonSecret.JWTHeader != "" {
+		return r.NonSecret.JWTHeader
+	}
+	return DefaultJWTHeader
+}
@@ -0,0 +1,114 @@
+package gitlab
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJwtTokenIsValidHS256(t *testing.T) {
+	r := &RegistryGitlab{
+		Name:   "my-registry",
+		Secret: Secret{JWTSecret: "  top-secret  \n"},
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	token, err := r.jwtToken(now)
+	if err != nil {
+		t.Fatalf("jwtToken() error = %v", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatalf("token is not valid base64: %v", err)
+	}
+
+	parts := strings.Split(string(decoded), ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 dot-separated parts, got %d: %q", len(parts), decoded)
+	}
+
+	mac := hmac.New(sha256.New, []byte("top-secret"))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	wantSig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if parts[2] != wantSig {
+		t.Fatalf("signature mismatch: got %s, want %s (trailing whitespace in the secret should be trimmed before signing)", parts[2], wantSig)
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("claims segment is not valid base64url: %v", err)
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatalf("claims segment is not valid JSON: %v", err)
+	}
+	if claims.Iat != now.Unix() {
+		t.Errorf("iat = %d, want %d", claims.Iat, now.Unix())
+	}
+	if got, want := claims.Exp-claims.Iat, int64(jwtLifetime.Seconds()); got != want {
+		t.Errorf("exp-iat = %d, want %d", got, want)
+	}
+	if claims.Jti == "" {
+		t.Error("jti must not be empty")
+	}
+}
+
+func TestJwtTokenJtiIsUniquePerCall(t *testing.T) {
+	r := &RegistryGitlab{Name: "my-registry", Secret: Secret{JWTSecret: "secret"}}
+	now := time.Now()
+
+	first, err := r.jwtToken(now)
+	if err != nil {
+		t.Fatalf("jwtToken() error = %v", err)
+	}
+	second, err := r.jwtToken(now)
+	if err != nil {
+		t.Fatalf("jwtToken() error = %v", err)
+	}
+	if first == second {
+		t.Error("two tokens minted at the same instant must still differ (jti must be unique per call)")
+	}
+}
+
+func TestSigningKeyReusesCachedKeyForSameSecret(t *testing.T) {
+	name := "cache-test-registry-stable"
+	key1 := signingKey(name, "my-secret")
+	key2 := signingKey(name, "my-secret")
+	if string(key1) != string(key2) {
+		t.Errorf("signingKey returned different bytes for the same secret: %q vs %q", key1, key2)
+	}
+}
+
+func TestSigningKeyRotatesInPlaceRatherThanAccumulating(t *testing.T) {
+	name := "cache-test-registry-rotate"
+	_ = signingKey(name, "old-secret")
+	rotated := signingKey(name, "new-secret")
+	if string(rotated) != "new-secret" {
+		t.Errorf("signingKey after rotation = %q, want %q", rotated, "new-secret")
+	}
+
+	cached, ok := signingKeys.Load(name)
+	if !ok {
+		t.Fatal("expected a cache entry for the registry name")
+	}
+	if entry := cached.(*cachedSigningKey); entry.secret != "new-secret" {
+		t.Errorf("cached secret = %q, want the rotated value %q", entry.secret, "new-secret")
+	}
+}
+
+func TestJwtHeaderNameDefaultsWhenUnset(t *testing.T) {
+	r := &RegistryGitlab{}
+	if got := r.jwtHeaderName(); got != DefaultJWTHeader {
+		t.Errorf("jwtHeaderName() = %q, want default %q", got, DefaultJWTHeader)
+	}
+
+	r.NonSecret.JWTHeader = "X-Custom-Header"
+	if got := r.jwtHeaderName(); got != "X-Custom-Header" {
+		t.Errorf("jwtHeaderName() = %q, want %q", got, "X-Custom-Header")
+	}
+}
@@ -1,5 +1,19 @@
 package gitlab
 
+// AuthMode selects how requests against the GitLab instance are
+// authenticated: a plain GitlabToken, or a JWT minted per request from a
+// shared secret.
+type AuthMode string
+
+const (
+	AuthModeToken AuthMode = "token"
+	AuthModeJWT   AuthMode = "jwt"
+)
+
+// DefaultJWTHeader is the header JWTs are sent in when NonSecret.JWTHeader
+// is left blank.
+const DefaultJWTHeader = "Gitlab-Shared-Secret"
+
 type RegistryGitlab struct {
 	Name         string    `json:"name" validate:"required,min=2,max=64"`
 	NonSecret    NonSecret `json:"non_secret" validate:"required"`
@@ -10,8 +24,35 @@ type RegistryGitlab struct {
 type NonSecret struct {
 	GitlabRegistryURL string `json:"gitlab_registry_url" validate:"required,min=2"`
 	GitlabServerURL   string `json:"gitlab_server_url" validate:"required,url"`
+	// CAFile is a base64-encoded PEM CA bundle used to verify the GitLab
+	// server certificate, for installs behind a private PKI.
+	CAFile string `json:"ca_file"`
+	// CAPath is a base64-encoded PEM CA bundle, kept as an alias of CAFile
+	// for parity with the `http.ca_path`/`http.ca_file` pair other Go
+	// clients to GitLab expose.
+	CAPath string `json:"ca_path"`
+	// SelfSigned skips verification of the GitLab server certificate
+	// entirely. Only used when no CAFile/CAPath is supplied.
+	SelfSigned bool `json:"self_signed"`
+	// AuthMode picks between GitlabToken and JWTSecret below. Defaults to
+	// AuthModeToken when left blank.
+	AuthMode AuthMode `json:"auth_mode" validate:"omitempty,oneof=token jwt"`
+	// JWTHeader is the header a minted JWT is sent in. Defaults to
+	// DefaultJWTHeader.
+	JWTHeader string `json:"jwt_header"`
 }
 
 type Secret struct {
-	GitlabToken string `json:"gitlab_access_token" validate:"required,min=2"`
+	// GitlabToken is required when AuthMode is AuthModeToken (the default).
+	GitlabToken string `json:"gitlab_access_token"`
+	// HTTPUser/HTTPPassword enable HTTP basic-auth on top of the access
+	// token (or the JWT in AuthModeJWT), for registries sitting behind a
+	// reverse-proxy that expects basic auth, mirroring
+	// `http.user`/`http.password`. They do not replace the credential
+	// Validate requires for the selected AuthMode.
+	HTTPUser     string `json:"http_user"`
+	HTTPPassword string `json:"http_password"`
+	// JWTSecret is required when AuthMode is AuthModeJWT. It is the shared
+	// secret used to HS256-sign a short-lived JWT per request.
+	JWTSecret string `json:"jwt_secret"`
 }